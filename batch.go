@@ -0,0 +1,317 @@
+package modbusclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// maxReadRegisters is the largest register count a single
+// ReadHoldingRegisters request can carry, per the Modbus FC 0x03 byte-count
+// limit of 250 bytes.
+const maxReadRegisters = 125
+
+// defaultBatchGap is the number of unused registers Batch bridges between
+// two fields' addresses before splitting them into separate reads.
+const defaultBatchGap = 8
+
+// ErrUnknownField is returned by a Result accessor for a field name that
+// was never added to the Batch that produced it.
+var ErrUnknownField = errors.New("unknown field")
+
+type fieldKind int
+
+const (
+	kindUint16 fieldKind = iota
+	kindInt16
+	kindUint32
+	kindInt32
+	kindFloat32
+	kindUint64
+	kindInt64
+	kindFloat64
+)
+
+// regs returns the number of consecutive holding registers a field of this
+// kind spans.
+func (k fieldKind) regs() uint16 {
+	switch k {
+	case kindUint16, kindInt16:
+		return 1
+	case kindUint32, kindInt32, kindFloat32:
+		return 2
+	default:
+		return 4
+	}
+}
+
+type field struct {
+	name      string
+	unitID    byte
+	addr      uint16
+	kind      fieldKind
+	wordOrder WordOrder
+}
+
+// decode reads this field's value out of frame, a normalized
+// ReadHoldingRegisters response, at offset, the byte offset of the field's
+// first register relative to its group's start address.
+func (f field) decode(c Client, frame []byte, offset int) (interface{}, error) {
+	switch f.kind {
+	case kindUint16:
+		return c.Uint16(frame, offset)
+	case kindInt16:
+		return c.Int16(frame, offset)
+	case kindUint32:
+		b, err := fieldBytes(frame, offset, 4)
+		if err != nil {
+			return nil, err
+		}
+		return decodeUint32(f.wordOrder, b), nil
+	case kindInt32:
+		b, err := fieldBytes(frame, offset, 4)
+		if err != nil {
+			return nil, err
+		}
+		return decodeInt32(f.wordOrder, b)
+	case kindFloat32:
+		b, err := fieldBytes(frame, offset, 4)
+		if err != nil {
+			return nil, err
+		}
+		return decodeFloat32(f.wordOrder, b)
+	case kindUint64:
+		b, err := fieldBytes(frame, offset, 8)
+		if err != nil {
+			return nil, err
+		}
+		return decodeUint64(f.wordOrder, b), nil
+	case kindInt64:
+		b, err := fieldBytes(frame, offset, 8)
+		if err != nil {
+			return nil, err
+		}
+		return decodeInt64(f.wordOrder, b)
+	default: // kindFloat64
+		b, err := fieldBytes(frame, offset, 8)
+		if err != nil {
+			return nil, err
+		}
+		return decodeFloat64(f.wordOrder, b)
+	}
+}
+
+func fieldBytes(frame []byte, offset int, n int) ([]byte, error) {
+	start := resHeaderLen + offset
+	if len(frame) < start+n {
+		return nil, ErrShortPayload
+	}
+	return frame[start : start+n], nil
+}
+
+// group is a single coalesced ReadHoldingRegisters request backing one or
+// more fields.
+type group struct {
+	unitID byte
+	addr   uint16
+	count  uint16
+	fields []field
+}
+
+// Batch groups holding-register reads for multiple named fields, across
+// unit ids and addresses, into as few ReadHoldingRegisters round-trips as
+// possible. Fields at adjacent or near-adjacent addresses for the same unit
+// id are coalesced into a single read, then sliced back into their typed
+// values on Poll.
+type Batch struct {
+	client Client
+	gap    uint16
+	fields []field
+}
+
+// NewBatch creates and returns a new Batch that polls c. gap is the number
+// of unused registers Batch will bridge between two fields before splitting
+// them into separate reads; pass 0 to use the default of defaultBatchGap
+// registers.
+func NewBatch(c Client, gap uint16) *Batch {
+	if gap == 0 {
+		gap = defaultBatchGap
+	}
+	return &Batch{client: c, gap: gap}
+}
+
+// AddUint16 adds a single-register field to the batch.
+func (b *Batch) AddUint16(name string, unitID byte, addr uint16) *Batch {
+	return b.add(name, unitID, addr, kindUint16, ABCD)
+}
+
+// AddInt16 adds a signed single-register field to the batch.
+func (b *Batch) AddInt16(name string, unitID byte, addr uint16) *Batch {
+	return b.add(name, unitID, addr, kindInt16, ABCD)
+}
+
+// AddUint32 adds a field spanning two registers to the batch, assembled
+// according to wordOrder.
+func (b *Batch) AddUint32(name string, unitID byte, addr uint16, wordOrder WordOrder) *Batch {
+	return b.add(name, unitID, addr, kindUint32, wordOrder)
+}
+
+// AddInt32 adds a signed field spanning two registers to the batch,
+// assembled according to wordOrder.
+func (b *Batch) AddInt32(name string, unitID byte, addr uint16, wordOrder WordOrder) *Batch {
+	return b.add(name, unitID, addr, kindInt32, wordOrder)
+}
+
+// AddFloat32 adds a float field spanning two registers to the batch,
+// assembled according to wordOrder.
+func (b *Batch) AddFloat32(name string, unitID byte, addr uint16, wordOrder WordOrder) *Batch {
+	return b.add(name, unitID, addr, kindFloat32, wordOrder)
+}
+
+// AddUint64 adds a field spanning four registers to the batch, assembled
+// according to wordOrder.
+func (b *Batch) AddUint64(name string, unitID byte, addr uint16, wordOrder WordOrder) *Batch {
+	return b.add(name, unitID, addr, kindUint64, wordOrder)
+}
+
+// AddInt64 adds a signed field spanning four registers to the batch,
+// assembled according to wordOrder.
+func (b *Batch) AddInt64(name string, unitID byte, addr uint16, wordOrder WordOrder) *Batch {
+	return b.add(name, unitID, addr, kindInt64, wordOrder)
+}
+
+// AddFloat64 adds a float field spanning four registers to the batch,
+// assembled according to wordOrder.
+func (b *Batch) AddFloat64(name string, unitID byte, addr uint16, wordOrder WordOrder) *Batch {
+	return b.add(name, unitID, addr, kindFloat64, wordOrder)
+}
+
+func (b *Batch) add(name string, unitID byte, addr uint16, kind fieldKind, wordOrder WordOrder) *Batch {
+	b.fields = append(b.fields, field{name: name, unitID: unitID, addr: addr, kind: kind, wordOrder: wordOrder})
+	return b
+}
+
+// plan groups Batch's fields by unit id, sorts them by address, and merges
+// them into the fewest groups possible: a field joins the current group if
+// it's within gap registers of the group's end and the combined span still
+// fits a single ReadHoldingRegisters request; otherwise it starts a new
+// group.
+func (b *Batch) plan() []group {
+	byUnit := make(map[byte][]field)
+	for _, f := range b.fields {
+		byUnit[f.unitID] = append(byUnit[f.unitID], f)
+	}
+
+	units := make([]byte, 0, len(byUnit))
+	for u := range byUnit {
+		units = append(units, u)
+	}
+	sort.Slice(units, func(i, j int) bool { return units[i] < units[j] })
+
+	var groups []group
+	for _, u := range units {
+		fields := byUnit[u]
+		sort.Slice(fields, func(i, j int) bool { return fields[i].addr < fields[j].addr })
+
+		for _, f := range fields {
+			end := f.addr + f.kind.regs()
+
+			if n := len(groups); n > 0 {
+				g := &groups[n-1]
+				if g.unitID == u && f.addr <= g.addr+g.count+b.gap && end-g.addr <= maxReadRegisters {
+					if end > g.addr+g.count {
+						g.count = end - g.addr
+					}
+					g.fields = append(g.fields, f)
+					continue
+				}
+			}
+
+			groups = append(groups, group{unitID: u, addr: f.addr, count: end - f.addr, fields: []field{f}})
+		}
+	}
+	return groups
+}
+
+// Poll executes one ReadHoldingRegisters round-trip per coalesced group of
+// fields and decodes each field's value out of the shared response. It
+// stops and returns the first error encountered, including any Modbus
+// exception reported by ReadErr, and checks ctx between round-trips so a
+// cancellation is honored before the next group's request is sent.
+func (b *Batch) Poll(ctx context.Context) (*Result, error) {
+	res := &Result{values: make(map[string]interface{}, len(b.fields))}
+
+	// The 2 extra bytes hold the CRC trailer an RTU transport reads into p.
+	p := make([]byte, resHeaderLen+maxReadRegisters*2+2)
+	for _, g := range b.plan() {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		n, err := b.client.Read(p, g.unitID, g.addr, g.count)
+		if err != nil {
+			return nil, err
+		}
+
+		frame := p[:n]
+		if err := b.client.ReadErr(frame, funcCodeReadHoldingRegisters); err != nil {
+			return nil, err
+		}
+
+		for _, f := range g.fields {
+			v, err := f.decode(b.client, frame, int(f.addr-g.addr)*2)
+			if err != nil {
+				return nil, err
+			}
+			res.values[f.name] = v
+		}
+	}
+
+	return res, nil
+}
+
+// Result holds the decoded values from a single Batch.Poll, keyed by field
+// name.
+type Result struct {
+	values map[string]interface{}
+}
+
+func resultValue[T any](r *Result, name string) (T, error) {
+	var zero T
+
+	v, ok := r.values[name]
+	if !ok {
+		return zero, fmt.Errorf("%w: %q", ErrUnknownField, name)
+	}
+
+	t, ok := v.(T)
+	if !ok {
+		return zero, fmt.Errorf("field %q is a %T, not a %T", name, v, zero)
+	}
+	return t, nil
+}
+
+// Uint16 returns the decoded value of the named Uint16 field.
+func (r *Result) Uint16(name string) (uint16, error) { return resultValue[uint16](r, name) }
+
+// Int16 returns the decoded value of the named Int16 field.
+func (r *Result) Int16(name string) (int16, error) { return resultValue[int16](r, name) }
+
+// Uint32 returns the decoded value of the named Uint32 field.
+func (r *Result) Uint32(name string) (uint32, error) { return resultValue[uint32](r, name) }
+
+// Int32 returns the decoded value of the named Int32 field.
+func (r *Result) Int32(name string) (int32, error) { return resultValue[int32](r, name) }
+
+// Float32 returns the decoded value of the named Float32 field.
+func (r *Result) Float32(name string) (float32, error) { return resultValue[float32](r, name) }
+
+// Uint64 returns the decoded value of the named Uint64 field.
+func (r *Result) Uint64(name string) (uint64, error) { return resultValue[uint64](r, name) }
+
+// Int64 returns the decoded value of the named Int64 field.
+func (r *Result) Int64(name string) (int64, error) { return resultValue[int64](r, name) }
+
+// Float64 returns the decoded value of the named Float64 field.
+func (r *Result) Float64(name string) (float64, error) { return resultValue[float64](r, name) }
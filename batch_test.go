@@ -0,0 +1,119 @@
+package modbusclient
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ermanimer/modbusclient/server"
+)
+
+func TestBatchPlanCoalescesAdjacentFields(t *testing.T) {
+	b := NewBatch(nil, 4)
+	b.AddUint16("a", 0x01, 0x0000)
+	b.AddUint16("b", 0x01, 0x0003)        // 2 registers of gap after "a" (ends at 1) — within gap 4
+	b.AddFloat32("c", 0x01, 0x0010, ABCD) // far away — starts a new group
+
+	groups := b.plan()
+	if len(groups) != 2 {
+		t.Fatalf("got %d groups, want 2", len(groups))
+	}
+
+	if groups[0].addr != 0x0000 || groups[0].count != 4 {
+		t.Errorf("group 0 = {addr: 0x%04x, count: %d}, want {addr: 0x0000, count: 4}", groups[0].addr, groups[0].count)
+	}
+	if len(groups[0].fields) != 2 {
+		t.Errorf("group 0 has %d fields, want 2", len(groups[0].fields))
+	}
+
+	if groups[1].addr != 0x0010 || groups[1].count != 2 {
+		t.Errorf("group 1 = {addr: 0x%04x, count: %d}, want {addr: 0x0010, count: 2}", groups[1].addr, groups[1].count)
+	}
+}
+
+func TestBatchPlanSplitsAcrossUnits(t *testing.T) {
+	b := NewBatch(nil, 8)
+	b.AddUint16("a", 0x01, 0x0000)
+	b.AddUint16("b", 0x02, 0x0000)
+
+	groups := b.plan()
+	if len(groups) != 2 {
+		t.Fatalf("got %d groups, want 2", len(groups))
+	}
+	if groups[0].unitID != 0x01 || groups[1].unitID != 0x02 {
+		t.Error("groups are not ordered by unit id", groups[0].unitID, groups[1].unitID)
+	}
+}
+
+func TestBatchPlanSplitsAtMaxReadRegisters(t *testing.T) {
+	// A huge gap threshold means the two fields would otherwise coalesce,
+	// but their combined span exceeds what a single ReadHoldingRegisters
+	// request can carry, so plan must still split them.
+	b := NewBatch(nil, 200)
+	b.AddUint16("a", 0x01, 0x0000)
+	b.AddUint16("b", 0x01, maxReadRegisters+5)
+
+	groups := b.plan()
+	if len(groups) != 2 {
+		t.Fatalf("got %d groups, want 2", len(groups))
+	}
+}
+
+func TestBatchPollAgainstServer(t *testing.T) {
+	store := server.NewMemoryStore()
+	store.SetHoldingRegister(0x0000, 42)
+	// 123.456 as float32, CDAB word order: register-swapped ABCD bytes.
+	store.SetHoldingRegister(0x0002, 0xE979)
+	store.SetHoldingRegister(0x0003, 0x42F6)
+
+	srv := server.New(store)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	addr, err := srv.Start(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer srv.Close()
+
+	c := NewClient(addr, time.Second, binary.BigEndian, ABCD)
+	if err := c.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	b := NewBatch(c, 8)
+	b.AddUint16("temp", 0x01, 0x0000)
+	b.AddFloat32("flow", 0x01, 0x0002, CDAB)
+
+	res, err := b.Poll(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	temp, err := res.Uint16("temp")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if temp != 42 {
+		t.Error("temp is not equal to expected", temp, 42)
+	}
+
+	flow, err := res.Float32("flow")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if flow != 123.456 {
+		t.Error("flow is not equal to expected", flow, 123.456)
+	}
+
+	if _, err := res.Uint16("missing"); !errors.Is(err, ErrUnknownField) {
+		t.Error("error is not ErrUnknownField")
+	}
+
+	if _, err := res.Int16("temp"); err == nil {
+		t.Error("expected a type mismatch error for temp as Int16")
+	}
+}
@@ -1,4 +1,5 @@
-// Package modbusclient implements Modbus TCP client.
+// Package modbusclient implements a Modbus client over TCP (MBAP framing),
+// RTU (serial), and RTU tunneled over TCP.
 package modbusclient
 
 import (
@@ -6,39 +7,132 @@ import (
 	"encoding/binary"
 	"errors"
 	"fmt"
-	"net"
+	"io"
 	"time"
 )
 
 // Errors:
 var (
-	ErrNotConnected  = errors.New("not connected")
-	ErrShortResponse = errors.New("short response")
-	ErrModbusError   = errors.New("modbus error")
-	ErrShortPayload  = errors.New("short payload")
+	ErrNotConnected        = errors.New("not connected")
+	ErrShortResponse       = errors.New("short response")
+	ErrModbusError         = errors.New("modbus error")
+	ErrShortPayload        = errors.New("short payload")
+	ErrTransactionMismatch = errors.New("transaction id mismatch")
+	ErrProtocolMismatch    = errors.New("protocol id mismatch")
 )
 
-// Modbus Parameters:
+// Modbus function codes:
 const (
-	readFuncCode     byte = 0x03
-	readResHeaderLen      = 9
-	errCodeIndex          = 7
-	excCodeIndex          = 8
+	funcCodeReadCoils                  byte = 0x01
+	funcCodeReadDiscreteInputs         byte = 0x02
+	funcCodeReadHoldingRegisters       byte = 0x03
+	funcCodeReadInputRegisters         byte = 0x04
+	funcCodeWriteSingleCoil            byte = 0x05
+	funcCodeWriteSingleRegister        byte = 0x06
+	funcCodeWriteMultipleCoils         byte = 0x0F
+	funcCodeWriteMultipleRegisters     byte = 0x10
+	funcCodeMaskWriteRegister          byte = 0x16
+	funcCodeReadWriteMultipleRegisters byte = 0x17
 )
 
-// Client defines the behaviors of a Modbus TCP Client.
+// WordOrder describes the register and byte ordering used to assemble
+// 32-bit and 64-bit values out of consecutive 16-bit Modbus registers. It
+// has no effect on single-register (16-bit) values, which are decoded
+// using Client's ByteOrder alone.
+type WordOrder int
+
+// Word orders:
+const (
+	// ABCD is standard big-endian order: registers in transmission order, each register's bytes most-significant-first.
+	ABCD WordOrder = iota
+	// CDAB swaps the register order while keeping each register's bytes most-significant-first. Commonly used by "Modbus float" devices.
+	CDAB
+	// BADC keeps the register order but swaps the two bytes within each register.
+	BADC
+	// DCBA reverses both the register order and the bytes within each register.
+	DCBA
+)
+
+// reorder rearranges the bytes of a multi-register payload into canonical
+// big-endian order according to w, so callers can always decode the result
+// with binary.BigEndian.
+func (w WordOrder) reorder(p []byte) []byte {
+	regs := len(p) / 2
+	out := make([]byte, len(p))
+	for i := 0; i < regs; i++ {
+		srcReg := i
+		if w == CDAB || w == DCBA {
+			srcReg = regs - 1 - i
+		}
+
+		hi, lo := p[srcReg*2], p[srcReg*2+1]
+		if w == BADC || w == DCBA {
+			hi, lo = lo, hi
+		}
+
+		out[i*2], out[i*2+1] = hi, lo
+	}
+	return out
+}
+
+// Modbus parameters:
+const (
+	// resHeaderLen is the length of the normalized response envelope every
+	// transport hands back to client: unit id, function code, and byte
+	// count or exception code.
+	resHeaderLen = 3
+	errCodeIndex = 1
+	excCodeIndex = 2
+
+	// coilOn and coilOff are the values used in the request payload of a
+	// WriteSingleCoil request to turn a coil on or off.
+	coilOn  uint16 = 0xFF00
+	coilOff uint16 = 0x0000
+)
+
+// Client defines the behaviors of a Modbus client.
 type Client interface {
-	// Connect uses net.DialTimeout to establish an underlying TCP connection with the Modbus TCP server.
+	// Connect establishes the underlying connection with the Modbus server: a TCP dial for NewClient and NewRTUOverTCP clients, or a no-op for NewRTU clients, which take an already-open port.
 	Connect() error
 
-	// SetDeadline sets the underlying TCP connection's deadline. Returns a modbusclient.ErrNotconnected if the client is not connected.
+	// SetDeadline sets the underlying connection's deadline. Returns a modbusclient.ErrNotconnected if the client is not connected.
 	SetDeadline(t time.Time) error
 
-	// Read reads data from the Holding Registers of a Modbus TCP device and writes it to the provided payload. Returns the read-byte count and a modbusclient.ErrNotconnected if the client is not connected to the server.
+	// Read reads data from the Holding Registers of a Modbus device and writes it to the provided payload. Returns the read-byte count and a modbusclient.ErrNotconnected if the client is not connected to the server.
 	Read(p []byte, unitID byte, addr uint16, count uint16) (n int, err error)
 
-	// ReadErr parses and returns the Modbus read error of the provided payload. Returns a modbusclient.ErrShortResponse if the payload is short.
-	ReadErr(p []byte) error
+	// ReadCoils reads the Coils of a Modbus device and writes the response to the provided payload. Returns the read-byte count and a modbusclient.ErrNotconnected if the client is not connected to the server.
+	ReadCoils(p []byte, unitID byte, addr uint16, count uint16) (n int, err error)
+
+	// ReadDiscreteInputs reads the Discrete Inputs of a Modbus device and writes the response to the provided payload. Returns the read-byte count and a modbusclient.ErrNotconnected if the client is not connected to the server.
+	ReadDiscreteInputs(p []byte, unitID byte, addr uint16, count uint16) (n int, err error)
+
+	// ReadInputRegisters reads the Input Registers of a Modbus device and writes the response to the provided payload. Returns the read-byte count and a modbusclient.ErrNotconnected if the client is not connected to the server.
+	ReadInputRegisters(p []byte, unitID byte, addr uint16, count uint16) (n int, err error)
+
+	// WriteSingleCoil writes a single Coil of a Modbus device and writes the response to the provided payload. Returns the read-byte count and a modbusclient.ErrNotconnected if the client is not connected to the server.
+	WriteSingleCoil(p []byte, unitID byte, addr uint16, on bool) (n int, err error)
+
+	// WriteSingleRegister writes a single Holding Register of a Modbus device and writes the response to the provided payload. Returns the read-byte count and a modbusclient.ErrNotconnected if the client is not connected to the server.
+	WriteSingleRegister(p []byte, unitID byte, addr uint16, value uint16) (n int, err error)
+
+	// WriteMultipleCoils writes multiple Coils of a Modbus device and writes the response to the provided payload. Returns the read-byte count and a modbusclient.ErrNotconnected if the client is not connected to the server.
+	WriteMultipleCoils(p []byte, unitID byte, addr uint16, values []bool) (n int, err error)
+
+	// WriteMultipleRegisters writes multiple Holding Registers of a Modbus device and writes the response to the provided payload. Returns the read-byte count and a modbusclient.ErrNotconnected if the client is not connected to the server.
+	WriteMultipleRegisters(p []byte, unitID byte, addr uint16, values []uint16) (n int, err error)
+
+	// MaskWriteRegister modifies a single Holding Register of a Modbus device using AND/OR masks and writes the response to the provided payload. Returns the read-byte count and a modbusclient.ErrNotconnected if the client is not connected to the server.
+	MaskWriteRegister(p []byte, unitID byte, addr uint16, andMask uint16, orMask uint16) (n int, err error)
+
+	// ReadWriteMultipleRegisters writes multiple Holding Registers and, in the same transaction, reads back a range of Holding Registers of a Modbus device, writing the response to the provided payload. Returns the read-byte count and a modbusclient.ErrNotconnected if the client is not connected to the server.
+	ReadWriteMultipleRegisters(p []byte, unitID byte, readAddr uint16, readCount uint16, writeAddr uint16, writeValues []uint16) (n int, err error)
+
+	// ReadErr parses and returns the Modbus read error of the provided payload for the given request function code. Returns a modbusclient.ErrShortResponse if the payload is short.
+	ReadErr(p []byte, funcCode byte) error
+
+	// Coil parses and returns a bit-packed coil or discrete input value at the given index from the provided payload. Returns a modbusclient.ErrShortPayload if the payload is short.
+	Coil(p []byte, index int) (bool, error)
 
 	// Uint16 parses and returns an uint16 value from the provided payload. Returns a modbusclient.ErrShortResponse if the payload is short.
 	Uint16(p []byte, offset int) (uint16, error)
@@ -46,80 +140,122 @@ type Client interface {
 	// Int16 parses and returns an int16 value from the provided payload. Returns a modbusclient.ErrShortResponse if the payload is short.
 	Int16(p []byte, offset int) (int16, error)
 
-	// Uint32 parses and returns an uint32 value from the provided payload. Returns a modbusclient.ErrShortResponse if the payload is short.
+	// Uint32 parses and returns an uint32 value from the provided payload, assembling its two registers according to Client's WordOrder. Returns a modbusclient.ErrShortPayload if the payload is short.
 	Uint32(p []byte, offset int) (uint32, error)
 
-	// Int32 parses and returns an int32 value from the provided payload. Returns a modbusclient.ErrShortResponse if the payload is short.
+	// Int32 parses and returns an int32 value from the provided payload, assembling its two registers according to Client's WordOrder. Returns a modbusclient.ErrShortPayload if the payload is short.
 	Int32(p []byte, offset int) (int32, error)
 
-	// Float32 parses and returns a float32 value from the provided payload. Returns a modbusclient.ErrShortResponse if the payload is short.
+	// Float32 parses and returns a float32 value from the provided payload, assembling its two registers according to Client's WordOrder. Returns a modbusclient.ErrShortPayload if the payload is short.
 	Float32(p []byte, offset int) (float32, error)
 
-	// Uint64 parses and returns an uint64 value from the provided payload. Returns a modbusclient.ErrShortResponse if the payload is short.
+	// Uint64 parses and returns an uint64 value from the provided payload, assembling its four registers according to Client's WordOrder. Returns a modbusclient.ErrShortPayload if the payload is short.
 	Uint64(p []byte, offset int) (uint64, error)
 
-	// Int64 parses and returns an int64 value from the provided payload. Returns a modbusclient.ErrShortResponse if the payload is short.
+	// Int64 parses and returns an int64 value from the provided payload, assembling its four registers according to Client's WordOrder. Returns a modbusclient.ErrShortPayload if the payload is short.
 	Int64(p []byte, offset int) (int64, error)
 
-	// Float64 parses and returns a float64 value from the provided payload. Returns a modbusclient.ErrShortResponse if the payload is short.
+	// Float64 parses and returns a float64 value from the provided payload, assembling its four registers according to Client's WordOrder. Returns a modbusclient.ErrShortPayload if the payload is short.
 	Float64(p []byte, offset int) (float64, error)
 
-	// Close closes the underlying TCP connection. Returns a modbusclient.ErrNotconnected if the client is not connected to the server.
+	// Close closes the underlying connection. Returns a modbusclient.ErrNotconnected if the client is not connected to the server.
 	Close() error
 }
 
 type client struct {
-	Addr        string
-	ConnTimeout time.Duration
-	ByteOrder   binary.ByteOrder
-	conn        net.Conn
+	transport transport
+	ByteOrder binary.ByteOrder
+	WordOrder WordOrder
 }
 
-// NewClient creates and returns a new Modbus TCP client.
-func NewClient(addr string, connTimeout time.Duration, byteOrder binary.ByteOrder) Client {
-	return &client{
-		Addr:        addr,
-		ConnTimeout: connTimeout,
-		ByteOrder:   byteOrder,
-	}
+// NewClient creates and returns a new Modbus TCP client using the standard
+// MBAP framing. wordOrder controls how 32-bit and 64-bit values spanning
+// multiple registers are assembled; pass modbusclient.ABCD for standard
+// big-endian devices.
+func NewClient(addr string, connTimeout time.Duration, byteOrder binary.ByteOrder, wordOrder WordOrder) Client {
+	return newClient(newTCPTransport(addr, connTimeout), byteOrder, wordOrder)
+}
+
+// NewRTU creates and returns a new Modbus RTU client that communicates over
+// an already-open serial port, framing requests and responses with a unit
+// id and a CRC-16/Modbus trailer instead of an MBAP header. baudRate is
+// used only to size the inter-frame silence the client leaves before each
+// request; it does not configure the port itself, which callers must open
+// at the matching baud rate, data bits, parity, and stop bits beforehand.
+func NewRTU(port io.ReadWriteCloser, baudRate int, byteOrder binary.ByteOrder, wordOrder WordOrder) Client {
+	return newClient(newRTUTransport(port, baudRate), byteOrder, wordOrder)
+}
+
+// NewRTUOverTCP creates and returns a new Modbus RTU client that tunnels RTU
+// frames (unit id, PDU, and a CRC-16/Modbus trailer) over a TCP socket,
+// without the MBAP header NewClient uses.
+func NewRTUOverTCP(addr string, connTimeout time.Duration, byteOrder binary.ByteOrder, wordOrder WordOrder) Client {
+	return newClient(newRTUOverTCPTransport(addr, connTimeout), byteOrder, wordOrder)
+}
+
+func newClient(t transport, byteOrder binary.ByteOrder, wordOrder WordOrder) Client {
+	return &client{transport: t, ByteOrder: byteOrder, WordOrder: wordOrder}
 }
 
 func (c *client) Connect() error {
-	conn, err := net.DialTimeout("tcp4", c.Addr, c.ConnTimeout)
-	if err != nil {
-		return err
-	}
-	c.conn = conn
-	return nil
+	return c.transport.connect()
 }
 
 func (c *client) SetDeadline(t time.Time) error {
-	if c.conn == nil {
-		return ErrNotConnected
-	}
-
-	return c.conn.SetDeadline(t)
+	return c.transport.setDeadline(t)
 }
 
 func (c *client) Read(p []byte, unitID byte, addr uint16, count uint16) (int, error) {
-	if c.conn == nil {
-		return 0, ErrNotConnected
-	}
+	return c.transport.do(p, unitID, makeReadPDU(funcCodeReadHoldingRegisters, addr, count))
+}
 
-	req := makeReadReq(unitID, addr, count)
-	if _, err := c.conn.Write(req); err != nil {
-		return 0, err
+func (c *client) ReadCoils(p []byte, unitID byte, addr uint16, count uint16) (int, error) {
+	return c.transport.do(p, unitID, makeReadPDU(funcCodeReadCoils, addr, count))
+}
+
+func (c *client) ReadDiscreteInputs(p []byte, unitID byte, addr uint16, count uint16) (int, error) {
+	return c.transport.do(p, unitID, makeReadPDU(funcCodeReadDiscreteInputs, addr, count))
+}
+
+func (c *client) ReadInputRegisters(p []byte, unitID byte, addr uint16, count uint16) (int, error) {
+	return c.transport.do(p, unitID, makeReadPDU(funcCodeReadInputRegisters, addr, count))
+}
+
+func (c *client) WriteSingleCoil(p []byte, unitID byte, addr uint16, on bool) (int, error) {
+	value := coilOff
+	if on {
+		value = coilOn
 	}
 
-	return c.conn.Read(p)
+	return c.transport.do(p, unitID, makeWriteSinglePDU(funcCodeWriteSingleCoil, addr, value))
+}
+
+func (c *client) WriteSingleRegister(p []byte, unitID byte, addr uint16, value uint16) (int, error) {
+	return c.transport.do(p, unitID, makeWriteSinglePDU(funcCodeWriteSingleRegister, addr, value))
+}
+
+func (c *client) WriteMultipleCoils(p []byte, unitID byte, addr uint16, values []bool) (int, error) {
+	return c.transport.do(p, unitID, makeWriteMultipleCoilsPDU(addr, values))
+}
+
+func (c *client) WriteMultipleRegisters(p []byte, unitID byte, addr uint16, values []uint16) (int, error) {
+	return c.transport.do(p, unitID, makeWriteMultipleRegistersPDU(addr, values))
+}
+
+func (c *client) MaskWriteRegister(p []byte, unitID byte, addr uint16, andMask uint16, orMask uint16) (int, error) {
+	return c.transport.do(p, unitID, makeMaskWriteRegisterPDU(addr, andMask, orMask))
+}
+
+func (c *client) ReadWriteMultipleRegisters(p []byte, unitID byte, readAddr uint16, readCount uint16, writeAddr uint16, writeValues []uint16) (int, error) {
+	return c.transport.do(p, unitID, makeReadWriteMultipleRegistersPDU(readAddr, readCount, writeAddr, writeValues))
 }
 
-func (c *client) ReadErr(p []byte) error {
-	if len(p) < readResHeaderLen {
+func (c *client) ReadErr(p []byte, funcCode byte) error {
+	if len(p) < resHeaderLen {
 		return ErrShortResponse
 	}
 
-	if errCode := p[errCodeIndex]; errCode != readFuncCode {
+	if errCode := p[errCodeIndex]; errCode != funcCode {
 		excCode := p[excCodeIndex]
 		return fmt.Errorf("%w, 0x%02x, 0x%02x", ErrModbusError, errCode, excCode)
 	}
@@ -127,8 +263,17 @@ func (c *client) ReadErr(p []byte) error {
 	return nil
 }
 
+func (c *client) Coil(p []byte, index int) (bool, error) {
+	offset := resHeaderLen + index/8
+	if len(p) <= offset {
+		return false, ErrShortPayload
+	}
+
+	return p[offset]&(1<<uint(index%8)) != 0, nil
+}
+
 func (c *client) Uint16(p []byte, offset int) (uint16, error) {
-	offset += readResHeaderLen
+	offset += resHeaderLen
 	if len(p) < offset+2 {
 		return 0, ErrShortPayload
 	}
@@ -137,7 +282,7 @@ func (c *client) Uint16(p []byte, offset int) (uint16, error) {
 }
 
 func (c *client) Int16(p []byte, offset int) (int16, error) {
-	offset += readResHeaderLen
+	offset += resHeaderLen
 	if len(p) < offset+2 {
 		return 0, ErrShortPayload
 	}
@@ -151,100 +296,212 @@ func (c *client) Int16(p []byte, offset int) (int16, error) {
 }
 
 func (c *client) Uint32(p []byte, offset int) (uint32, error) {
-	offset += readResHeaderLen
+	offset += resHeaderLen
 	if len(p) < offset+4 {
 		return 0, ErrShortPayload
 	}
 
-	return c.ByteOrder.Uint32(p[offset : offset+4]), nil
+	return decodeUint32(c.WordOrder, p[offset:offset+4]), nil
 }
 
 func (c *client) Int32(p []byte, offset int) (int32, error) {
-	offset += readResHeaderLen
+	offset += resHeaderLen
 	if len(p) < offset+4 {
 		return 0, ErrShortPayload
 	}
 
-	r := bytes.NewReader(p[offset : offset+4])
-	var v int32
-	if err := binary.Read(r, c.ByteOrder, &v); err != nil {
-		return 0, err
-	}
-	return v, nil
+	return decodeInt32(c.WordOrder, p[offset:offset+4])
 }
 
 func (c *client) Float32(p []byte, offset int) (float32, error) {
-	offset += readResHeaderLen
+	offset += resHeaderLen
 	if len(p) < offset+4 {
 		return 0, ErrShortPayload
 	}
 
-	r := bytes.NewReader(p[offset : offset+4])
-	var v float32
-	if err := binary.Read(r, c.ByteOrder, &v); err != nil {
-		return 0, err
-	}
-	return v, nil
+	return decodeFloat32(c.WordOrder, p[offset:offset+4])
 }
 
 func (c *client) Uint64(p []byte, offset int) (uint64, error) {
-	offset += readResHeaderLen
+	offset += resHeaderLen
 	if len(p) < offset+8 {
 		return 0, ErrShortPayload
 	}
 
-	return c.ByteOrder.Uint64(p[offset : offset+8]), nil
+	return decodeUint64(c.WordOrder, p[offset:offset+8]), nil
 }
 
 func (c *client) Int64(p []byte, offset int) (int64, error) {
-	offset += readResHeaderLen
+	offset += resHeaderLen
 	if len(p) < offset+8 {
 		return 0, ErrShortPayload
 	}
 
-	r := bytes.NewReader(p[offset : offset+8])
-	var v int64
-	if err := binary.Read(r, c.ByteOrder, &v); err != nil {
-		return 0, err
-	}
-	return v, nil
+	return decodeInt64(c.WordOrder, p[offset:offset+8])
 }
 
 func (c *client) Float64(p []byte, offset int) (float64, error) {
-	offset += readResHeaderLen
+	offset += resHeaderLen
 	if len(p) < offset+8 {
 		return 0, ErrShortPayload
 	}
 
-	r := bytes.NewReader(p[offset : offset+8])
-	var v float64
-	if err := binary.Read(r, c.ByteOrder, &v); err != nil {
+	return decodeFloat64(c.WordOrder, p[offset:offset+8])
+}
+
+func (c *client) Close() error {
+	return c.transport.close()
+}
+
+// decodeUint32, decodeInt32, decodeFloat32, decodeUint64, decodeInt64, and
+// decodeFloat64 assemble a multi-register value out of p according to
+// wordOrder. They back both Client's own decode methods and Batch, which
+// needs to apply a word order per field rather than per client.
+func decodeUint32(wordOrder WordOrder, p []byte) uint32 {
+	return binary.BigEndian.Uint32(wordOrder.reorder(p))
+}
+
+func decodeInt32(wordOrder WordOrder, p []byte) (int32, error) {
+	r := bytes.NewReader(wordOrder.reorder(p))
+	var v int32
+	if err := binary.Read(r, binary.BigEndian, &v); err != nil {
 		return 0, err
 	}
 	return v, nil
 }
 
-func (c *client) Close() error {
-	if c.conn == nil {
-		return ErrNotConnected
+func decodeFloat32(wordOrder WordOrder, p []byte) (float32, error) {
+	r := bytes.NewReader(wordOrder.reorder(p))
+	var v float32
+	if err := binary.Read(r, binary.BigEndian, &v); err != nil {
+		return 0, err
+	}
+	return v, nil
+}
+
+func decodeUint64(wordOrder WordOrder, p []byte) uint64 {
+	return binary.BigEndian.Uint64(wordOrder.reorder(p))
+}
+
+func decodeInt64(wordOrder WordOrder, p []byte) (int64, error) {
+	r := bytes.NewReader(wordOrder.reorder(p))
+	var v int64
+	if err := binary.Read(r, binary.BigEndian, &v); err != nil {
+		return 0, err
 	}
+	return v, nil
+}
 
-	return c.conn.Close()
+func decodeFloat64(wordOrder WordOrder, p []byte) (float64, error) {
+	r := bytes.NewReader(wordOrder.reorder(p))
+	var v float64
+	if err := binary.Read(r, binary.BigEndian, &v); err != nil {
+		return 0, err
+	}
+	return v, nil
 }
 
-func makeReadReq(unitID byte, addr uint16, count uint16) []byte {
+// makeReadPDU builds the PDU (function code plus data) for a Modbus read
+// request. Transports prepend whatever unit id and framing their medium
+// requires.
+func makeReadPDU(funcCode byte, addr uint16, count uint16) []byte {
 	return []byte{
-		0x00,                      // transaction id, high
-		0x00,                      // transcation id, low
-		0x00,                      // protocol id, high
-		0x00,                      // protocol id, low
-		0x00,                      // length, high
-		0x06,                      // length, low
-		unitID,                    // unit id
-		readFuncCode,              // function code
+		funcCode,                  // function code
 		byte((addr >> 8) & 0xFF),  // address, high
 		byte(addr & 0xFF),         // address, low
 		byte((count >> 8) & 0xFF), // register count, high
 		byte(count & 0xFF),        // register count, low
 	}
 }
+
+func makeWriteSinglePDU(funcCode byte, addr uint16, value uint16) []byte {
+	return []byte{
+		funcCode,                  // function code
+		byte((addr >> 8) & 0xFF),  // address, high
+		byte(addr & 0xFF),         // address, low
+		byte((value >> 8) & 0xFF), // value, high
+		byte(value & 0xFF),        // value, low
+	}
+}
+
+func makeWriteMultipleCoilsPDU(addr uint16, values []bool) []byte {
+	data := packCoils(values)
+	byteCount := byte(len(data))
+	count := uint16(len(values))
+
+	pdu := []byte{
+		funcCodeWriteMultipleCoils, // function code
+		byte((addr >> 8) & 0xFF),   // address, high
+		byte(addr & 0xFF),          // address, low
+		byte((count >> 8) & 0xFF),  // coil count, high
+		byte(count & 0xFF),         // coil count, low
+		byteCount,                  // byte count
+	}
+	return append(pdu, data...)
+}
+
+func makeWriteMultipleRegistersPDU(addr uint16, values []uint16) []byte {
+	data := make([]byte, 0, len(values)*2)
+	for _, v := range values {
+		data = append(data, byte((v>>8)&0xFF), byte(v&0xFF))
+	}
+	byteCount := byte(len(data))
+	count := uint16(len(values))
+
+	pdu := []byte{
+		funcCodeWriteMultipleRegisters, // function code
+		byte((addr >> 8) & 0xFF),       // address, high
+		byte(addr & 0xFF),              // address, low
+		byte((count >> 8) & 0xFF),      // register count, high
+		byte(count & 0xFF),             // register count, low
+		byteCount,                      // byte count
+	}
+	return append(pdu, data...)
+}
+
+func makeMaskWriteRegisterPDU(addr uint16, andMask uint16, orMask uint16) []byte {
+	return []byte{
+		funcCodeMaskWriteRegister,   // function code
+		byte((addr >> 8) & 0xFF),    // address, high
+		byte(addr & 0xFF),           // address, low
+		byte((andMask >> 8) & 0xFF), // and-mask, high
+		byte(andMask & 0xFF),        // and-mask, low
+		byte((orMask >> 8) & 0xFF),  // or-mask, high
+		byte(orMask & 0xFF),         // or-mask, low
+	}
+}
+
+func makeReadWriteMultipleRegistersPDU(readAddr uint16, readCount uint16, writeAddr uint16, writeValues []uint16) []byte {
+	data := make([]byte, 0, len(writeValues)*2)
+	for _, v := range writeValues {
+		data = append(data, byte((v>>8)&0xFF), byte(v&0xFF))
+	}
+	byteCount := byte(len(data))
+	writeCount := uint16(len(writeValues))
+
+	pdu := []byte{
+		funcCodeReadWriteMultipleRegisters, // function code
+		byte((readAddr >> 8) & 0xFF),       // read address, high
+		byte(readAddr & 0xFF),              // read address, low
+		byte((readCount >> 8) & 0xFF),      // read count, high
+		byte(readCount & 0xFF),             // read count, low
+		byte((writeAddr >> 8) & 0xFF),      // write address, high
+		byte(writeAddr & 0xFF),             // write address, low
+		byte((writeCount >> 8) & 0xFF),     // write count, high
+		byte(writeCount & 0xFF),            // write count, low
+		byteCount,                          // byte count
+	}
+	return append(pdu, data...)
+}
+
+// packCoils packs a slice of coil states into a bit-packed byte slice, least
+// significant bit first, as used by the Modbus write-multiple-coils request.
+func packCoils(values []bool) []byte {
+	data := make([]byte, (len(values)+7)/8)
+	for i, v := range values {
+		if v {
+			data[i/8] |= 1 << uint(i%8)
+		}
+	}
+	return data
+}
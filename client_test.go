@@ -15,17 +15,11 @@ func TestReadErr(t *testing.T) {
 	var errCode byte = 0x83
 	var excCode byte = 0x01
 	p := []byte{
-		0x00,
-		0x00,
-		0x00,
-		0x00,
-		0x00,
-		0x06,
 		0x01,
 		errCode,
 		excCode,
 	}
-	err := c.ReadErr(p)
+	err := c.ReadErr(p, funcCodeReadHoldingRegisters)
 	if !errors.Is(err, ErrModbusError) {
 		t.Error("read error is not ErrModbusError")
 	}
@@ -37,6 +31,90 @@ func TestReadErr(t *testing.T) {
 	}
 }
 
+func TestCoil(t *testing.T) {
+	c := &client{}
+
+	p := make([]byte, resHeaderLen+1)
+	p[resHeaderLen] = 0x05 // 0b00000101: coil 0 and coil 2 set
+
+	v, err := c.Coil(p, 0)
+	if err != nil {
+		t.Error(err)
+	}
+	if !v {
+		t.Error("coil 0 is not set")
+	}
+
+	v, err = c.Coil(p, 1)
+	if err != nil {
+		t.Error(err)
+	}
+	if v {
+		t.Error("coil 1 is set")
+	}
+
+	v, err = c.Coil(p, 2)
+	if err != nil {
+		t.Error(err)
+	}
+	if !v {
+		t.Error("coil 2 is not set")
+	}
+
+	_, err = c.Coil(p, 8)
+	if !errors.Is(err, ErrShortPayload) {
+		t.Error("error is not ErrShortPayload")
+	}
+}
+
+func TestMakeReadPDU(t *testing.T) {
+	pdu := makeReadPDU(funcCodeReadCoils, 0x0010, 0x0002)
+	expected := []byte{funcCodeReadCoils, 0x00, 0x10, 0x00, 0x02}
+	if !bytes.Equal(pdu, expected) {
+		t.Error("pdu is not equal to expected", pdu, expected)
+	}
+}
+
+func TestMakeWriteSinglePDU(t *testing.T) {
+	pdu := makeWriteSinglePDU(funcCodeWriteSingleCoil, 0x0010, coilOn)
+	expected := []byte{funcCodeWriteSingleCoil, 0x00, 0x10, 0xFF, 0x00}
+	if !bytes.Equal(pdu, expected) {
+		t.Error("pdu is not equal to expected", pdu, expected)
+	}
+}
+
+func TestMakeWriteMultipleCoilsPDU(t *testing.T) {
+	pdu := makeWriteMultipleCoilsPDU(0x0010, []bool{true, false, true})
+	expected := []byte{funcCodeWriteMultipleCoils, 0x00, 0x10, 0x00, 0x03, 0x01, 0x05}
+	if !bytes.Equal(pdu, expected) {
+		t.Error("pdu is not equal to expected", pdu, expected)
+	}
+}
+
+func TestMakeWriteMultipleRegistersPDU(t *testing.T) {
+	pdu := makeWriteMultipleRegistersPDU(0x0010, []uint16{0x0001, 0x0002})
+	expected := []byte{funcCodeWriteMultipleRegisters, 0x00, 0x10, 0x00, 0x02, 0x04, 0x00, 0x01, 0x00, 0x02}
+	if !bytes.Equal(pdu, expected) {
+		t.Error("pdu is not equal to expected", pdu, expected)
+	}
+}
+
+func TestMakeMaskWriteRegisterPDU(t *testing.T) {
+	pdu := makeMaskWriteRegisterPDU(0x0010, 0x00F2, 0x0025)
+	expected := []byte{funcCodeMaskWriteRegister, 0x00, 0x10, 0x00, 0xF2, 0x00, 0x25}
+	if !bytes.Equal(pdu, expected) {
+		t.Error("pdu is not equal to expected", pdu, expected)
+	}
+}
+
+func TestMakeReadWriteMultipleRegistersPDU(t *testing.T) {
+	pdu := makeReadWriteMultipleRegistersPDU(0x0010, 0x0002, 0x0020, []uint16{0x0001})
+	expected := []byte{funcCodeReadWriteMultipleRegisters, 0x00, 0x10, 0x00, 0x02, 0x00, 0x20, 0x00, 0x01, 0x02, 0x00, 0x01}
+	if !bytes.Equal(pdu, expected) {
+		t.Error("pdu is not equal to expected", pdu, expected)
+	}
+}
+
 func TestErrShortPayload(t *testing.T) {
 	c := &client{
 		ByteOrder: binary.BigEndian,
@@ -91,8 +169,8 @@ func TestUint16(t *testing.T) {
 	}
 
 	var expected uint16 = 1
-	p := make([]byte, readResHeaderLen+2)
-	c.ByteOrder.PutUint16(p[readResHeaderLen:], expected)
+	p := make([]byte, resHeaderLen+2)
+	c.ByteOrder.PutUint16(p[resHeaderLen:], expected)
 
 	v, err := c.Uint16(p, 0)
 	if err != nil {
@@ -109,7 +187,7 @@ func TestInt16(t *testing.T) {
 	}
 
 	var expected int16 = 1
-	p := make([]byte, readResHeaderLen)
+	p := make([]byte, resHeaderLen)
 	w := bytes.NewBuffer(p)
 	binary.Write(w, c.ByteOrder, expected)
 	p = w.Bytes()
@@ -129,8 +207,8 @@ func TestUint32(t *testing.T) {
 	}
 
 	var expected uint32 = 1
-	p := make([]byte, readResHeaderLen+4)
-	c.ByteOrder.PutUint32(p[readResHeaderLen:], expected)
+	p := make([]byte, resHeaderLen+4)
+	c.ByteOrder.PutUint32(p[resHeaderLen:], expected)
 
 	v, err := c.Uint32(p, 0)
 	if err != nil {
@@ -147,7 +225,7 @@ func TestInt32(t *testing.T) {
 	}
 
 	var expected int32 = 1
-	p := make([]byte, readResHeaderLen)
+	p := make([]byte, resHeaderLen)
 	w := bytes.NewBuffer(p)
 	binary.Write(w, c.ByteOrder, expected)
 	p = w.Bytes()
@@ -167,7 +245,7 @@ func TestFloat32(t *testing.T) {
 	}
 
 	var expected float32 = 1
-	p := make([]byte, readResHeaderLen)
+	p := make([]byte, resHeaderLen)
 	w := bytes.NewBuffer(p)
 	binary.Write(w, c.ByteOrder, expected)
 	p = w.Bytes()
@@ -181,14 +259,43 @@ func TestFloat32(t *testing.T) {
 	}
 }
 
+func TestFloat32WordOrders(t *testing.T) {
+	const expected float32 = 123.456
+
+	cases := []struct {
+		wordOrder WordOrder
+		data      []byte
+	}{
+		{ABCD, []byte{0x42, 0xF6, 0xE9, 0x79}},
+		{CDAB, []byte{0xE9, 0x79, 0x42, 0xF6}},
+		{BADC, []byte{0xF6, 0x42, 0x79, 0xE9}},
+		{DCBA, []byte{0x79, 0xE9, 0xF6, 0x42}},
+	}
+
+	for _, tc := range cases {
+		c := &client{WordOrder: tc.wordOrder}
+
+		p := make([]byte, resHeaderLen)
+		p = append(p, tc.data...)
+
+		v, err := c.Float32(p, 0)
+		if err != nil {
+			t.Error(err)
+		}
+		if v != expected {
+			t.Errorf("word order %v: value is not equal to expected, got %v, want %v", tc.wordOrder, v, expected)
+		}
+	}
+}
+
 func TestUint64(t *testing.T) {
 	c := &client{
 		ByteOrder: binary.BigEndian,
 	}
 
 	var expected uint64 = 1
-	p := make([]byte, readResHeaderLen+8)
-	c.ByteOrder.PutUint64(p[readResHeaderLen:], expected)
+	p := make([]byte, resHeaderLen+8)
+	c.ByteOrder.PutUint64(p[resHeaderLen:], expected)
 
 	v, err := c.Uint64(p, 0)
 	if err != nil {
@@ -205,7 +312,7 @@ func TestInt64(t *testing.T) {
 	}
 
 	var expected int64 = 1
-	p := make([]byte, readResHeaderLen)
+	p := make([]byte, resHeaderLen)
 	w := bytes.NewBuffer(p)
 	binary.Write(w, c.ByteOrder, expected)
 	p = w.Bytes()
@@ -225,7 +332,7 @@ func TestFloat64(t *testing.T) {
 	}
 
 	var expected float64 = 1
-	p := make([]byte, readResHeaderLen)
+	p := make([]byte, resHeaderLen)
 	w := bytes.NewBuffer(p)
 	binary.Write(w, c.ByteOrder, expected)
 	p = w.Bytes()
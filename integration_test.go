@@ -0,0 +1,112 @@
+package modbusclient
+
+import (
+	"context"
+	"encoding/binary"
+	"testing"
+	"time"
+
+	"github.com/ermanimer/modbusclient/server"
+)
+
+func TestClientReadHoldingRegistersAgainstServer(t *testing.T) {
+	store := server.NewMemoryStore()
+	store.SetHoldingRegister(0x0010, 42)
+
+	srv := server.New(store)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	addr, err := srv.Start(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer srv.Close()
+
+	c := NewClient(addr, time.Second, binary.BigEndian, ABCD)
+	if err := c.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	p := make([]byte, 256)
+	n, err := c.Read(p, 0x01, 0x0010, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p = p[:n]
+
+	if err := c.ReadErr(p, funcCodeReadHoldingRegisters); err != nil {
+		t.Fatal(err)
+	}
+
+	v, err := c.Uint16(p, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v != 42 {
+		t.Error("value is not equal to expected", v, 42)
+	}
+}
+
+func TestClientWriteSingleCoilAgainstServer(t *testing.T) {
+	store := server.NewMemoryStore()
+
+	srv := server.New(store)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	addr, err := srv.Start(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer srv.Close()
+
+	c := NewClient(addr, time.Second, binary.BigEndian, ABCD)
+	if err := c.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	p := make([]byte, 256)
+	if _, err := c.WriteSingleCoil(p, 0x01, 0x0020, true); err != nil {
+		t.Fatal(err)
+	}
+
+	if !store.ReadCoil(0x0020) {
+		t.Error("coil was not set")
+	}
+}
+
+func TestClientReadAgainstScriptedException(t *testing.T) {
+	store := server.HandlerFunc(func(unitID byte, funcCode byte, pdu []byte) ([]byte, byte, bool) {
+		return nil, server.ExcIllegalDataAddress, false
+	})
+
+	srv := server.New(store)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	addr, err := srv.Start(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer srv.Close()
+
+	c := NewClient(addr, time.Second, binary.BigEndian, ABCD)
+	if err := c.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	p := make([]byte, 256)
+	n, err := c.Read(p, 0x01, 0x0010, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p = p[:n]
+
+	if err := c.ReadErr(p, funcCodeReadHoldingRegisters); err == nil {
+		t.Error("read error is nil")
+	}
+}
@@ -0,0 +1,229 @@
+package modbusclient
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"time"
+)
+
+// ErrUnitMismatch is returned by an RTU transport when a response's unit id
+// does not match the unit id the request was sent to.
+var ErrUnitMismatch = errors.New("unit id mismatch")
+
+// ErrCRCMismatch is returned by an RTU transport when a response's CRC-16
+// trailer does not match the frame it was read with.
+var ErrCRCMismatch = errors.New("crc mismatch")
+
+// rtuMinSilence is the fixed minimum inter-frame silence used at baud rates
+// above 19200, per the Modbus RTU spec.
+const rtuMinSilence = 1750 * time.Microsecond
+
+// rtuTransport frames requests and responses as Modbus RTU over an
+// already-open serial port: unit id, PDU, and a CRC-16/Modbus trailer, with
+// no MBAP header. Port boundaries are not length-delimited, so silence on
+// the line rather than a length field is how a slave knows a frame is
+// finished; rtuTransport only needs to respect the equivalent silence
+// before it starts writing its own request.
+type rtuTransport struct {
+	port     io.ReadWriteCloser
+	baudRate int
+
+	mu     sync.Mutex
+	lastIO time.Time
+}
+
+func newRTUTransport(port io.ReadWriteCloser, baudRate int) *rtuTransport {
+	return &rtuTransport{port: port, baudRate: baudRate}
+}
+
+func (t *rtuTransport) connect() error {
+	if t.port == nil {
+		return ErrNotConnected
+	}
+	return nil
+}
+
+func (t *rtuTransport) setDeadline(d time.Time) error {
+	if t.port == nil {
+		return ErrNotConnected
+	}
+
+	type deadliner interface {
+		SetDeadline(t time.Time) error
+	}
+	dl, ok := t.port.(deadliner)
+	if !ok {
+		return nil
+	}
+	return dl.SetDeadline(d)
+}
+
+func (t *rtuTransport) do(p []byte, unitID byte, pdu []byte) (int, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.port == nil {
+		return 0, ErrNotConnected
+	}
+
+	if wait := t.interFrameDelay() - time.Since(t.lastIO); wait > 0 {
+		time.Sleep(wait)
+	}
+
+	n, err := rtuDo(t.port, p, unitID, pdu)
+	t.lastIO = time.Now()
+	return n, err
+}
+
+// interFrameDelay returns the minimum silence an RTU master must leave on
+// the line before starting a new frame: 3.5 character times at the
+// configured baud rate, or the fixed 1.75ms floor used above 19200 baud.
+func (t *rtuTransport) interFrameDelay() time.Duration {
+	if t.baudRate <= 0 || t.baudRate > 19200 {
+		return rtuMinSilence
+	}
+
+	// Each serial character is 11 bits (start bit, 8 data bits, parity or
+	// extra stop bit, stop bit).
+	return time.Duration(float64(11*3.5) * float64(time.Second) / float64(t.baudRate))
+}
+
+func (t *rtuTransport) close() error {
+	if t.port == nil {
+		return ErrNotConnected
+	}
+
+	return t.port.Close()
+}
+
+// rtuOverTCPTransport frames requests and responses as Modbus RTU (unit id,
+// PDU, and a CRC-16/Modbus trailer) but carries them over a TCP connection
+// instead of a serial line, with no MBAP header. Unlike rtuTransport, the
+// connection is already reliably framed by TCP delivery order, so no
+// inter-frame silence is needed between requests.
+type rtuOverTCPTransport struct {
+	addr        string
+	connTimeout time.Duration
+	conn        net.Conn
+}
+
+func newRTUOverTCPTransport(addr string, connTimeout time.Duration) *rtuOverTCPTransport {
+	return &rtuOverTCPTransport{addr: addr, connTimeout: connTimeout}
+}
+
+func (t *rtuOverTCPTransport) connect() error {
+	conn, err := net.DialTimeout("tcp4", t.addr, t.connTimeout)
+	if err != nil {
+		return err
+	}
+	t.conn = conn
+	return nil
+}
+
+func (t *rtuOverTCPTransport) setDeadline(d time.Time) error {
+	if t.conn == nil {
+		return ErrNotConnected
+	}
+
+	return t.conn.SetDeadline(d)
+}
+
+func (t *rtuOverTCPTransport) do(p []byte, unitID byte, pdu []byte) (int, error) {
+	if t.conn == nil {
+		return 0, ErrNotConnected
+	}
+
+	return rtuDo(t.conn, p, unitID, pdu)
+}
+
+func (t *rtuOverTCPTransport) close() error {
+	if t.conn == nil {
+		return ErrNotConnected
+	}
+
+	return t.conn.Close()
+}
+
+// rtuDo writes unitID and pdu to rw as a Modbus RTU frame (unit id, PDU,
+// CRC-16/Modbus trailer), reads back just enough of the reply to determine
+// its length from the function code and, for byte-counted responses, the
+// byte count, then reads and validates the rest of the frame. p is left
+// holding the normalized [unit id, function code, byte count or exception
+// code, data...] response with the CRC trailer stripped.
+func rtuDo(rw io.ReadWriter, p []byte, unitID byte, pdu []byte) (int, error) {
+	req := make([]byte, 0, 1+len(pdu)+2)
+	req = append(req, unitID)
+	req = append(req, pdu...)
+	crc := crc16(req)
+	req = append(req, byte(crc), byte(crc>>8))
+
+	if _, err := rw.Write(req); err != nil {
+		return 0, err
+	}
+
+	head := make([]byte, 3)
+	if _, err := io.ReadFull(rw, head); err != nil {
+		return 0, err
+	}
+
+	if head[0] != unitID {
+		return 0, fmt.Errorf("%w, got 0x%02x, want 0x%02x", ErrUnitMismatch, head[0], unitID)
+	}
+
+	n := 3 + rtuRemaining(head)
+	if len(p) < n {
+		return 0, ErrShortResponse
+	}
+
+	copy(p, head)
+	if _, err := io.ReadFull(rw, p[3:n]); err != nil {
+		return 0, err
+	}
+
+	data, got := p[:n-2], uint16(p[n-2])|uint16(p[n-1])<<8
+	if want := crc16(data); got != want {
+		return 0, fmt.Errorf("%w, got 0x%04x, want 0x%04x", ErrCRCMismatch, got, want)
+	}
+
+	return n - 2, nil
+}
+
+// rtuRemaining returns the number of response bytes remaining after head,
+// the first 3 bytes of an RTU response (unit id, function code, and byte
+// count or exception code), including the trailing CRC.
+func rtuRemaining(head []byte) int {
+	if head[1]&0x80 != 0 {
+		return 2 // head[2] is already the exception code; only the CRC is left.
+	}
+
+	switch head[1] {
+	case funcCodeReadCoils, funcCodeReadDiscreteInputs, funcCodeReadHoldingRegisters, funcCodeReadInputRegisters, funcCodeReadWriteMultipleRegisters:
+		return int(head[2]) + 2 // head[2] is the byte count; data, then the CRC.
+	case funcCodeMaskWriteRegister:
+		return 7 // addr low byte(1) + and-mask(2) + or-mask(2) + crc(2); the addr high byte is already in head.
+	default:
+		// WriteSingleCoil, WriteSingleRegister, WriteMultipleCoils, and
+		// WriteMultipleRegisters all echo a fixed 4-byte body (address plus
+		// value or count) followed by the CRC.
+		return 5
+	}
+}
+
+// crc16 computes the CRC-16/Modbus checksum of data.
+func crc16(data []byte) uint16 {
+	crc := uint16(0xFFFF)
+	for _, b := range data {
+		crc ^= uint16(b)
+		for i := 0; i < 8; i++ {
+			if crc&1 != 0 {
+				crc = (crc >> 1) ^ 0xA001
+			} else {
+				crc >>= 1
+			}
+		}
+	}
+	return crc
+}
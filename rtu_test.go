@@ -0,0 +1,179 @@
+package modbusclient
+
+import (
+	"errors"
+	"io"
+	"net"
+	"testing"
+)
+
+// pipePort adapts two io.Pipes, one per direction, into an io.ReadWriteCloser
+// pair suitable for exercising rtuTransport without real serial hardware.
+func pipePort() (serverSide io.ReadWriteCloser, clientSide io.ReadWriteCloser) {
+	reqR, reqW := io.Pipe()
+	resR, resW := io.Pipe()
+	return rwCloser{reqR, resW}, rwCloser{resR, reqW}
+}
+
+// rwCloser composes a reader and writer (each half of an io.Pipe, which has
+// no Close) into a single io.ReadWriteCloser.
+type rwCloser struct {
+	io.Reader
+	io.Writer
+}
+
+func (rwCloser) Close() error { return nil }
+
+func TestCRC16(t *testing.T) {
+	// Read Holding Registers request for unit 1, address 0x0000, count 1;
+	// a well-known CRC-16/Modbus test vector.
+	req := []byte{0x01, 0x03, 0x00, 0x00, 0x00, 0x01}
+	if got, want := crc16(req), uint16(0x0A84); got != want {
+		t.Errorf("crc16 = 0x%04x, want 0x%04x", got, want)
+	}
+}
+
+func TestRTUTransportReadHoldingRegisters(t *testing.T) {
+	serverSide, clientSide := pipePort()
+	tr := newRTUTransport(clientSide, 19200)
+
+	go func() {
+		req := make([]byte, 8)
+		if _, err := io.ReadFull(serverSide, req); err != nil {
+			return
+		}
+
+		res := []byte{0x01, 0x03, 0x02, 0x00, 0x2A}
+		crc := crc16(res)
+		res = append(res, byte(crc), byte(crc>>8))
+		serverSide.Write(res)
+	}()
+
+	p := make([]byte, 256)
+	n, err := tr.do(p, 0x01, makeReadPDU(funcCodeReadHoldingRegisters, 0x0010, 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []byte{0x01, 0x03, 0x02, 0x00, 0x2A}
+	if string(p[:n]) != string(expected) {
+		t.Error("response is not equal to expected", p[:n], expected)
+	}
+}
+
+func TestRTUTransportDetectsCRCMismatch(t *testing.T) {
+	serverSide, clientSide := pipePort()
+	tr := newRTUTransport(clientSide, 19200)
+
+	go func() {
+		req := make([]byte, 8)
+		if _, err := io.ReadFull(serverSide, req); err != nil {
+			return
+		}
+
+		res := []byte{0x01, 0x03, 0x02, 0x00, 0x2A, 0x00, 0x00} // bad CRC
+		serverSide.Write(res)
+	}()
+
+	p := make([]byte, 256)
+	_, err := tr.do(p, 0x01, makeReadPDU(funcCodeReadHoldingRegisters, 0x0010, 1))
+	if !errors.Is(err, ErrCRCMismatch) {
+		t.Error("error is not ErrCRCMismatch")
+	}
+}
+
+func TestRTUTransportDetectsUnitMismatch(t *testing.T) {
+	serverSide, clientSide := pipePort()
+	tr := newRTUTransport(clientSide, 19200)
+
+	go func() {
+		req := make([]byte, 8)
+		if _, err := io.ReadFull(serverSide, req); err != nil {
+			return
+		}
+
+		res := []byte{0x02, 0x03, 0x02, 0x00, 0x2A}
+		crc := crc16(res)
+		res = append(res, byte(crc), byte(crc>>8))
+		serverSide.Write(res)
+	}()
+
+	p := make([]byte, 256)
+	_, err := tr.do(p, 0x01, makeReadPDU(funcCodeReadHoldingRegisters, 0x0010, 1))
+	if !errors.Is(err, ErrUnitMismatch) {
+		t.Error("error is not ErrUnitMismatch")
+	}
+}
+
+func TestRTUTransportDetectsException(t *testing.T) {
+	serverSide, clientSide := pipePort()
+	tr := newRTUTransport(clientSide, 19200)
+
+	go func() {
+		req := make([]byte, 8)
+		if _, err := io.ReadFull(serverSide, req); err != nil {
+			return
+		}
+
+		const excIllegalDataAddress = 0x02
+		res := []byte{0x01, funcCodeReadHoldingRegisters | 0x80, excIllegalDataAddress}
+		crc := crc16(res)
+		res = append(res, byte(crc), byte(crc>>8))
+		serverSide.Write(res)
+	}()
+
+	p := make([]byte, 256)
+	n, err := tr.do(p, 0x01, makeReadPDU(funcCodeReadHoldingRegisters, 0x0010, 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c := &client{}
+	if err := c.ReadErr(p[:n], funcCodeReadHoldingRegisters); !errors.Is(err, ErrModbusError) {
+		t.Error("error is not ErrModbusError")
+	}
+}
+
+func TestRTUTransportInterFrameDelay(t *testing.T) {
+	fast := &rtuTransport{baudRate: 115200}
+	if got, want := fast.interFrameDelay(), rtuMinSilence; got != want {
+		t.Errorf("interFrameDelay() above 19200 baud = %v, want the %v floor", got, want)
+	}
+
+	slow := &rtuTransport{baudRate: 9600}
+	if got := slow.interFrameDelay(); got <= rtuMinSilence {
+		t.Errorf("interFrameDelay() at 9600 baud = %v, want more than the %v floor", got, rtuMinSilence)
+	}
+}
+
+// rtuOverTCPTransport.do is a thin wrapper around rtuDo over a net.Conn;
+// rtuDo's framing logic (shared with rtuTransport) is exercised above, so
+// here we only confirm the wrapper is wired up correctly using the same
+// net.Pipe helper the tcpTransport tests use.
+func TestRTUOverTCPTransportReadHoldingRegisters(t *testing.T) {
+	serverConn, clientConn := net.Pipe()
+	tr := &rtuOverTCPTransport{conn: clientConn}
+
+	go func() {
+		req := make([]byte, 8)
+		if _, err := io.ReadFull(serverConn, req); err != nil {
+			return
+		}
+
+		res := []byte{0x01, 0x03, 0x02, 0x00, 0x2A}
+		crc := crc16(res)
+		res = append(res, byte(crc), byte(crc>>8))
+		serverConn.Write(res)
+	}()
+
+	p := make([]byte, 256)
+	n, err := tr.do(p, 0x01, makeReadPDU(funcCodeReadHoldingRegisters, 0x0010, 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []byte{0x01, 0x03, 0x02, 0x00, 0x2A}
+	if string(p[:n]) != string(expected) {
+		t.Error("response is not equal to expected", p[:n], expected)
+	}
+}
@@ -0,0 +1,403 @@
+// Package server implements a minimal Modbus TCP server backed by a
+// pluggable Store, intended for use in hermetic tests and examples against
+// modbusclient.Client.
+package server
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"sync"
+)
+
+// Modbus function codes handled by the server.
+const (
+	funcCodeReadCoils                  byte = 0x01
+	funcCodeReadDiscreteInputs         byte = 0x02
+	funcCodeReadHoldingRegisters       byte = 0x03
+	funcCodeReadInputRegisters         byte = 0x04
+	funcCodeWriteSingleCoil            byte = 0x05
+	funcCodeWriteSingleRegister        byte = 0x06
+	funcCodeWriteMultipleCoils         byte = 0x0F
+	funcCodeWriteMultipleRegisters     byte = 0x10
+	funcCodeMaskWriteRegister          byte = 0x16
+	funcCodeReadWriteMultipleRegisters byte = 0x17
+)
+
+// Modbus exception codes.
+const (
+	ExcIllegalFunction     byte = 0x01
+	ExcIllegalDataAddress  byte = 0x02
+	ExcIllegalDataValue    byte = 0x03
+	ExcServerDeviceFailure byte = 0x04
+)
+
+// ErrClosed is returned by Close if the server was never started.
+var ErrClosed = errors.New("server closed")
+
+// Store defines the behavior of a register/coil store that backs a Server's
+// responses to Modbus requests. Handle receives the unit id, function code,
+// and the request PDU bytes that follow the function code, and returns the
+// response PDU bytes to frame back to the client. If ok is false, the
+// server frames excCode as a Modbus exception response instead.
+type Store interface {
+	Handle(unitID byte, funcCode byte, pdu []byte) (resPDU []byte, excCode byte, ok bool)
+}
+
+// HandlerFunc adapts a function to a Store, letting tests inject scripted
+// responses, including exceptions, without implementing the Store interface.
+type HandlerFunc func(unitID byte, funcCode byte, pdu []byte) (resPDU []byte, excCode byte, ok bool)
+
+// Handle calls f.
+func (f HandlerFunc) Handle(unitID byte, funcCode byte, pdu []byte) ([]byte, byte, bool) {
+	return f(unitID, funcCode, pdu)
+}
+
+// Server is a minimal Modbus TCP server.
+type Server struct {
+	store    Store
+	listener net.Listener
+}
+
+// New creates and returns a new Server backed by store.
+func New(store Store) *Server {
+	return &Server{store: store}
+}
+
+// Start listens on 127.0.0.1 on an arbitrary free port and starts serving
+// requests in the background until ctx is done or Close is called. It
+// returns the address clients should dial.
+func (s *Server) Start(ctx context.Context) (string, error) {
+	l, err := net.Listen("tcp4", "127.0.0.1:0")
+	if err != nil {
+		return "", err
+	}
+	s.listener = l
+
+	go func() {
+		<-ctx.Done()
+		s.listener.Close()
+	}()
+
+	go s.serve()
+
+	return l.Addr().String(), nil
+}
+
+// Close stops the server and closes its listener.
+func (s *Server) Close() error {
+	if s.listener == nil {
+		return ErrClosed
+	}
+
+	return s.listener.Close()
+}
+
+func (s *Server) serve() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+
+		go s.handleConn(conn)
+	}
+}
+
+func (s *Server) handleConn(conn net.Conn) {
+	defer conn.Close()
+
+	for {
+		header := make([]byte, 7)
+		if _, err := io.ReadFull(conn, header); err != nil {
+			return
+		}
+
+		length := binary.BigEndian.Uint16(header[4:6])
+		if length < 1 {
+			return
+		}
+
+		body := make([]byte, length-1)
+		if _, err := io.ReadFull(conn, body); err != nil {
+			return
+		}
+
+		if len(body) < 1 {
+			return
+		}
+
+		unitID := header[6]
+		funcCode := body[0]
+		res := s.handleReq(unitID, funcCode, body[1:])
+
+		frame := make([]byte, 6, 6+len(res))
+		copy(frame, header[:4])
+		resLen := uint16(len(res))
+		binary.BigEndian.PutUint16(frame[4:6], resLen)
+		frame = append(frame, res...)
+
+		if _, err := conn.Write(frame); err != nil {
+			return
+		}
+	}
+}
+
+func (s *Server) handleReq(unitID byte, funcCode byte, pdu []byte) []byte {
+	resPDU, excCode, ok := s.store.Handle(unitID, funcCode, pdu)
+	if !ok {
+		return []byte{unitID, funcCode | 0x80, excCode}
+	}
+
+	res := make([]byte, 2, 2+len(resPDU))
+	res[0] = unitID
+	res[1] = funcCode
+	return append(res, resPDU...)
+}
+
+// MemoryStore is a Store backed by in-memory coil, discrete input, holding
+// register, and input register maps, keyed by address.
+type MemoryStore struct {
+	mu        sync.Mutex
+	coils     map[uint16]bool
+	discretes map[uint16]bool
+	holding   map[uint16]uint16
+	input     map[uint16]uint16
+}
+
+// NewMemoryStore creates and returns a new, empty MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		coils:     make(map[uint16]bool),
+		discretes: make(map[uint16]bool),
+		holding:   make(map[uint16]uint16),
+		input:     make(map[uint16]uint16),
+	}
+}
+
+// SetCoil sets the value of the coil at addr.
+func (s *MemoryStore) SetCoil(addr uint16, v bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.coils[addr] = v
+}
+
+// ReadCoil returns the value of the coil at addr.
+func (s *MemoryStore) ReadCoil(addr uint16) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.coils[addr]
+}
+
+// SetDiscreteInput sets the value of the discrete input at addr.
+func (s *MemoryStore) SetDiscreteInput(addr uint16, v bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.discretes[addr] = v
+}
+
+// ReadDiscreteInput returns the value of the discrete input at addr.
+func (s *MemoryStore) ReadDiscreteInput(addr uint16) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.discretes[addr]
+}
+
+// SetHoldingRegister sets the value of the holding register at addr.
+func (s *MemoryStore) SetHoldingRegister(addr uint16, v uint16) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.holding[addr] = v
+}
+
+// ReadHoldingRegister returns the value of the holding register at addr.
+func (s *MemoryStore) ReadHoldingRegister(addr uint16) uint16 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.holding[addr]
+}
+
+// SetInputRegister sets the value of the input register at addr.
+func (s *MemoryStore) SetInputRegister(addr uint16, v uint16) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.input[addr] = v
+}
+
+// ReadInputRegister returns the value of the input register at addr.
+func (s *MemoryStore) ReadInputRegister(addr uint16) uint16 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.input[addr]
+}
+
+// Handle implements Store.
+func (s *MemoryStore) Handle(unitID byte, funcCode byte, pdu []byte) ([]byte, byte, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch funcCode {
+	case funcCodeReadCoils:
+		return s.readBits(pdu, s.coils)
+	case funcCodeReadDiscreteInputs:
+		return s.readBits(pdu, s.discretes)
+	case funcCodeReadHoldingRegisters:
+		return s.readRegs(pdu, s.holding)
+	case funcCodeReadInputRegisters:
+		return s.readRegs(pdu, s.input)
+	case funcCodeWriteSingleCoil:
+		return s.writeSingleCoil(pdu)
+	case funcCodeWriteSingleRegister:
+		return s.writeSingleRegister(pdu)
+	case funcCodeWriteMultipleCoils:
+		return s.writeMultipleCoils(pdu)
+	case funcCodeWriteMultipleRegisters:
+		return s.writeMultipleRegisters(pdu)
+	case funcCodeMaskWriteRegister:
+		return s.maskWriteRegister(pdu)
+	case funcCodeReadWriteMultipleRegisters:
+		return s.readWriteMultipleRegisters(pdu)
+	default:
+		return nil, ExcIllegalFunction, false
+	}
+}
+
+func (s *MemoryStore) readBits(pdu []byte, store map[uint16]bool) ([]byte, byte, bool) {
+	if len(pdu) < 4 {
+		return nil, ExcIllegalDataValue, false
+	}
+
+	addr := binary.BigEndian.Uint16(pdu[0:2])
+	count := binary.BigEndian.Uint16(pdu[2:4])
+
+	data := make([]byte, (count+7)/8)
+	for i := uint16(0); i < count; i++ {
+		if store[addr+i] {
+			data[i/8] |= 1 << uint(i%8)
+		}
+	}
+
+	return append([]byte{byte(len(data))}, data...), 0, true
+}
+
+func (s *MemoryStore) readRegs(pdu []byte, store map[uint16]uint16) ([]byte, byte, bool) {
+	if len(pdu) < 4 {
+		return nil, ExcIllegalDataValue, false
+	}
+
+	addr := binary.BigEndian.Uint16(pdu[0:2])
+	count := binary.BigEndian.Uint16(pdu[2:4])
+
+	data := make([]byte, count*2)
+	for i := uint16(0); i < count; i++ {
+		binary.BigEndian.PutUint16(data[i*2:i*2+2], store[addr+i])
+	}
+
+	return append([]byte{byte(len(data))}, data...), 0, true
+}
+
+func (s *MemoryStore) writeSingleCoil(pdu []byte) ([]byte, byte, bool) {
+	if len(pdu) < 4 {
+		return nil, ExcIllegalDataValue, false
+	}
+
+	addr := binary.BigEndian.Uint16(pdu[0:2])
+	value := binary.BigEndian.Uint16(pdu[2:4])
+	s.coils[addr] = value == 0xFF00
+
+	return append([]byte{}, pdu[:4]...), 0, true
+}
+
+func (s *MemoryStore) writeSingleRegister(pdu []byte) ([]byte, byte, bool) {
+	if len(pdu) < 4 {
+		return nil, ExcIllegalDataValue, false
+	}
+
+	addr := binary.BigEndian.Uint16(pdu[0:2])
+	value := binary.BigEndian.Uint16(pdu[2:4])
+	s.holding[addr] = value
+
+	return append([]byte{}, pdu[:4]...), 0, true
+}
+
+func (s *MemoryStore) writeMultipleCoils(pdu []byte) ([]byte, byte, bool) {
+	if len(pdu) < 5 {
+		return nil, ExcIllegalDataValue, false
+	}
+
+	addr := binary.BigEndian.Uint16(pdu[0:2])
+	count := binary.BigEndian.Uint16(pdu[2:4])
+	data := pdu[5:]
+
+	if uint16(len(data)) < (count+7)/8 {
+		return nil, ExcIllegalDataValue, false
+	}
+
+	for i := uint16(0); i < count; i++ {
+		s.coils[addr+i] = data[i/8]&(1<<uint(i%8)) != 0
+	}
+
+	return pdu[:4], 0, true
+}
+
+func (s *MemoryStore) writeMultipleRegisters(pdu []byte) ([]byte, byte, bool) {
+	if len(pdu) < 5 {
+		return nil, ExcIllegalDataValue, false
+	}
+
+	addr := binary.BigEndian.Uint16(pdu[0:2])
+	count := binary.BigEndian.Uint16(pdu[2:4])
+	data := pdu[5:]
+
+	if uint16(len(data)) < count*2 {
+		return nil, ExcIllegalDataValue, false
+	}
+
+	for i := uint16(0); i < count; i++ {
+		s.holding[addr+i] = binary.BigEndian.Uint16(data[i*2 : i*2+2])
+	}
+
+	return pdu[:4], 0, true
+}
+
+func (s *MemoryStore) maskWriteRegister(pdu []byte) ([]byte, byte, bool) {
+	if len(pdu) < 6 {
+		return nil, ExcIllegalDataValue, false
+	}
+
+	addr := binary.BigEndian.Uint16(pdu[0:2])
+	andMask := binary.BigEndian.Uint16(pdu[2:4])
+	orMask := binary.BigEndian.Uint16(pdu[4:6])
+	s.holding[addr] = (s.holding[addr] & andMask) | (orMask & ^andMask)
+
+	return append([]byte{}, pdu[:6]...), 0, true
+}
+
+func (s *MemoryStore) readWriteMultipleRegisters(pdu []byte) ([]byte, byte, bool) {
+	if len(pdu) < 9 {
+		return nil, ExcIllegalDataValue, false
+	}
+
+	readAddr := binary.BigEndian.Uint16(pdu[0:2])
+	readCount := binary.BigEndian.Uint16(pdu[2:4])
+	writeAddr := binary.BigEndian.Uint16(pdu[4:6])
+	writeCount := binary.BigEndian.Uint16(pdu[6:8])
+	data := pdu[9:]
+
+	if uint16(len(data)) < writeCount*2 {
+		return nil, ExcIllegalDataValue, false
+	}
+
+	for i := uint16(0); i < writeCount; i++ {
+		s.holding[writeAddr+i] = binary.BigEndian.Uint16(data[i*2 : i*2+2])
+	}
+
+	resData := make([]byte, readCount*2)
+	for i := uint16(0); i < readCount; i++ {
+		binary.BigEndian.PutUint16(resData[i*2:i*2+2], s.holding[readAddr+i])
+	}
+
+	return append([]byte{byte(len(resData))}, resData...), 0, true
+}
@@ -0,0 +1,153 @@
+package server
+
+import (
+	"context"
+	"encoding/binary"
+	"net"
+	"testing"
+)
+
+func TestMemoryStoreReadCoils(t *testing.T) {
+	s := NewMemoryStore()
+	s.SetCoil(0x0001, true)
+
+	pdu := []byte{0x00, 0x00, 0x00, 0x08}
+	res, excCode, ok := s.Handle(0x01, funcCodeReadCoils, pdu)
+	if !ok {
+		t.Fatalf("unexpected exception, 0x%02x", excCode)
+	}
+
+	expected := []byte{0x01, 0x02}
+	if string(res) != string(expected) {
+		t.Error("response is not equal to expected", res, expected)
+	}
+}
+
+func TestMemoryStoreReadHoldingRegisters(t *testing.T) {
+	s := NewMemoryStore()
+	s.SetHoldingRegister(0x0010, 7)
+
+	pdu := []byte{0x00, 0x10, 0x00, 0x01}
+	res, excCode, ok := s.Handle(0x01, funcCodeReadHoldingRegisters, pdu)
+	if !ok {
+		t.Fatalf("unexpected exception, 0x%02x", excCode)
+	}
+
+	if res[0] != 2 {
+		t.Error("byte count is not equal to expected", res[0], 2)
+	}
+	if v := binary.BigEndian.Uint16(res[1:3]); v != 7 {
+		t.Error("value is not equal to expected", v, 7)
+	}
+}
+
+func TestMemoryStoreWriteSingleRegister(t *testing.T) {
+	s := NewMemoryStore()
+
+	pdu := []byte{0x00, 0x10, 0x00, 0x2A}
+	if _, _, ok := s.Handle(0x01, funcCodeWriteSingleRegister, pdu); !ok {
+		t.Fatal("unexpected exception")
+	}
+
+	if v := s.ReadHoldingRegister(0x0010); v != 0x2A {
+		t.Error("value is not equal to expected", v, 0x2A)
+	}
+}
+
+func TestMemoryStoreMaskWriteRegister(t *testing.T) {
+	s := NewMemoryStore()
+	s.SetHoldingRegister(0x0010, 0x0012)
+
+	pdu := []byte{0x00, 0x10, 0x00, 0xF2, 0x00, 0x25}
+	if _, _, ok := s.Handle(0x01, funcCodeMaskWriteRegister, pdu); !ok {
+		t.Fatal("unexpected exception")
+	}
+
+	if v := s.ReadHoldingRegister(0x0010); v != 0x17 {
+		t.Error("value is not equal to expected", v, 0x17)
+	}
+}
+
+func TestMemoryStoreWriteMultipleCoilsShortData(t *testing.T) {
+	s := NewMemoryStore()
+
+	pdu := []byte{0x00, 0x00, 0x00, 0x40, 0x08, 0x00}
+	_, excCode, ok := s.Handle(0x01, funcCodeWriteMultipleCoils, pdu)
+	if ok {
+		t.Fatal("expected exception")
+	}
+	if excCode != ExcIllegalDataValue {
+		t.Error("exception code is not equal to expected", excCode, ExcIllegalDataValue)
+	}
+}
+
+func TestMemoryStoreWriteMultipleRegistersShortData(t *testing.T) {
+	s := NewMemoryStore()
+
+	pdu := []byte{0x00, 0x10, 0x00, 0x02, 0x04, 0x00, 0x01}
+	_, excCode, ok := s.Handle(0x01, funcCodeWriteMultipleRegisters, pdu)
+	if ok {
+		t.Fatal("expected exception")
+	}
+	if excCode != ExcIllegalDataValue {
+		t.Error("exception code is not equal to expected", excCode, ExcIllegalDataValue)
+	}
+}
+
+func TestMemoryStoreIllegalFunction(t *testing.T) {
+	s := NewMemoryStore()
+
+	_, excCode, ok := s.Handle(0x01, 0x7F, nil)
+	if ok {
+		t.Fatal("expected exception")
+	}
+	if excCode != ExcIllegalFunction {
+		t.Error("exception code is not equal to expected", excCode, ExcIllegalFunction)
+	}
+}
+
+func TestServerHandleConnEmptyBody(t *testing.T) {
+	srv := New(NewMemoryStore())
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	addr, err := srv.Start(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer srv.Close()
+
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	// MBAP header declaring length == 1, i.e. a unit id with no function
+	// code byte following it.
+	frame := []byte{0x00, 0x01, 0x00, 0x00, 0x00, 0x01, 0x01}
+	if _, err := conn.Write(frame); err != nil {
+		t.Fatal(err)
+	}
+
+	// The connection should be closed by the server instead of panicking;
+	// a subsequent read must return EOF rather than hang.
+	p := make([]byte, 1)
+	if _, err := conn.Read(p); err == nil {
+		t.Fatal("expected connection to be closed")
+	}
+}
+
+func TestHandlerFunc(t *testing.T) {
+	var store Store = HandlerFunc(func(unitID byte, funcCode byte, pdu []byte) ([]byte, byte, bool) {
+		return []byte{0x2A}, 0, true
+	})
+
+	res, _, ok := store.Handle(0x01, funcCodeReadHoldingRegisters, nil)
+	if !ok {
+		t.Fatal("unexpected exception")
+	}
+	if len(res) != 1 || res[0] != 0x2A {
+		t.Error("response is not equal to expected", res)
+	}
+}
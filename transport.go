@@ -0,0 +1,125 @@
+package modbusclient
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"time"
+)
+
+// Modbus TCP (MBAP) parameters:
+const mbapHeaderLen = 7
+
+// transport is the wire-level behavior a Client needs: frame a unit id and
+// PDU for the underlying medium, send it, and return the response
+// normalized to [unit id, function code, byte count or exception code,
+// data...], regardless of how that medium frames or terminates messages.
+type transport interface {
+	connect() error
+	setDeadline(t time.Time) error
+	do(p []byte, unitID byte, pdu []byte) (n int, err error)
+	close() error
+}
+
+// tcpTransport frames requests and responses using the Modbus MBAP header
+// over a TCP connection.
+type tcpTransport struct {
+	addr        string
+	connTimeout time.Duration
+	conn        net.Conn
+
+	txIDMu sync.Mutex
+	txID   uint16
+}
+
+func newTCPTransport(addr string, connTimeout time.Duration) *tcpTransport {
+	return &tcpTransport{addr: addr, connTimeout: connTimeout}
+}
+
+func (t *tcpTransport) connect() error {
+	conn, err := net.DialTimeout("tcp4", t.addr, t.connTimeout)
+	if err != nil {
+		return err
+	}
+	t.conn = conn
+	return nil
+}
+
+func (t *tcpTransport) setDeadline(d time.Time) error {
+	if t.conn == nil {
+		return ErrNotConnected
+	}
+
+	return t.conn.SetDeadline(d)
+}
+
+// do stamps the MBAP header with the next transaction id, writes it followed
+// by unitID and pdu to the underlying connection, and reads the framed
+// response into p. The MBAP header is read first to determine the payload
+// length, then the remainder of the frame is read in full, so a short TCP
+// read never leaves the caller with a truncated frame. The MBAP header
+// itself is stripped from p; p is left holding [unit id, function code,
+// byte count or exception code, data...].
+func (t *tcpTransport) do(p []byte, unitID byte, pdu []byte) (int, error) {
+	if t.conn == nil {
+		return 0, ErrNotConnected
+	}
+
+	txID := t.nextTxID()
+	length := uint16(1 + len(pdu))
+
+	req := make([]byte, 0, mbapHeaderLen+len(pdu))
+	req = append(req, byte(txID>>8), byte(txID), 0x00, 0x00, byte(length>>8), byte(length), unitID)
+	req = append(req, pdu...)
+
+	if _, err := t.conn.Write(req); err != nil {
+		return 0, err
+	}
+
+	header := make([]byte, mbapHeaderLen)
+	if _, err := io.ReadFull(t.conn, header); err != nil {
+		return 0, err
+	}
+
+	if got := binary.BigEndian.Uint16(header[0:2]); got != txID {
+		return 0, fmt.Errorf("%w, got 0x%04x, want 0x%04x", ErrTransactionMismatch, got, txID)
+	}
+
+	if protocolID := binary.BigEndian.Uint16(header[2:4]); protocolID != 0 {
+		return 0, fmt.Errorf("%w, 0x%04x", ErrProtocolMismatch, protocolID)
+	}
+
+	resLength := int(binary.BigEndian.Uint16(header[4:6]))
+	if resLength < 1 {
+		return 0, ErrShortResponse
+	}
+
+	if len(p) < resLength {
+		return 0, ErrShortResponse
+	}
+
+	p[0] = header[6]
+	if _, err := io.ReadFull(t.conn, p[1:resLength]); err != nil {
+		return 0, err
+	}
+
+	return resLength, nil
+}
+
+// nextTxID returns the next monotonically increasing MBAP transaction id.
+func (t *tcpTransport) nextTxID() uint16 {
+	t.txIDMu.Lock()
+	defer t.txIDMu.Unlock()
+	t.txID++
+	return t.txID
+}
+
+func (t *tcpTransport) close() error {
+	if t.conn == nil {
+		return ErrNotConnected
+	}
+
+	return t.conn.Close()
+}
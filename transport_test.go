@@ -0,0 +1,90 @@
+package modbusclient
+
+import (
+	"errors"
+	"io"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestTCPTransportReassemblesFragmentedFrame(t *testing.T) {
+	serverConn, clientConn := net.Pipe()
+	tr := &tcpTransport{conn: clientConn}
+
+	go func() {
+		req := make([]byte, 12)
+		io.ReadFull(serverConn, req)
+		txID := req[0:2]
+
+		res := []byte{txID[0], txID[1], 0x00, 0x00, 0x00, 0x05, 0x01, 0x03, 0x02, 0x00, 0x2A}
+		// Dribble the response out in single bytes to simulate a fragmented TCP stream.
+		for _, b := range res {
+			serverConn.Write([]byte{b})
+		}
+	}()
+
+	p := make([]byte, 256)
+	n, err := tr.do(p, 0x01, makeReadPDU(funcCodeReadHoldingRegisters, 0x0010, 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := 5
+	if n != expected {
+		t.Error("read-byte count is not equal to expected", n, expected)
+	}
+}
+
+func TestTCPTransportDetectsTransactionMismatch(t *testing.T) {
+	serverConn, clientConn := net.Pipe()
+	tr := &tcpTransport{conn: clientConn}
+
+	go func() {
+		req := make([]byte, 12)
+		io.ReadFull(serverConn, req)
+
+		res := []byte{0xFF, 0xFF, 0x00, 0x00, 0x00, 0x05, 0x01, 0x03, 0x02, 0x00, 0x2A}
+		serverConn.Write(res)
+	}()
+
+	p := make([]byte, 256)
+	_, err := tr.do(p, 0x01, makeReadPDU(funcCodeReadHoldingRegisters, 0x0010, 1))
+	if !errors.Is(err, ErrTransactionMismatch) {
+		t.Error("error is not ErrTransactionMismatch")
+	}
+}
+
+func TestTCPTransportDetectsProtocolMismatch(t *testing.T) {
+	serverConn, clientConn := net.Pipe()
+	tr := &tcpTransport{conn: clientConn}
+
+	go func() {
+		req := make([]byte, 12)
+		io.ReadFull(serverConn, req)
+		txID := req[0:2]
+
+		res := []byte{txID[0], txID[1], 0x00, 0x01, 0x00, 0x05, 0x01, 0x03, 0x02, 0x00, 0x2A}
+		serverConn.Write(res)
+	}()
+
+	p := make([]byte, 256)
+	_, err := tr.do(p, 0x01, makeReadPDU(funcCodeReadHoldingRegisters, 0x0010, 1))
+	if !errors.Is(err, ErrProtocolMismatch) {
+		t.Error("error is not ErrProtocolMismatch")
+	}
+}
+
+func TestTCPTransportNotConnected(t *testing.T) {
+	tr := &tcpTransport{}
+
+	if _, err := tr.do(make([]byte, 256), 0x01, makeReadPDU(funcCodeReadHoldingRegisters, 0x0010, 1)); !errors.Is(err, ErrNotConnected) {
+		t.Error("error is not ErrNotConnected")
+	}
+	if err := tr.setDeadline(time.Time{}); !errors.Is(err, ErrNotConnected) {
+		t.Error("error is not ErrNotConnected")
+	}
+	if err := tr.close(); !errors.Is(err, ErrNotConnected) {
+		t.Error("error is not ErrNotConnected")
+	}
+}